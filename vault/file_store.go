@@ -0,0 +1,291 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Scrypt parameters for deriving the per-file AES-256 key from the
+// configured passphrase. These match the parameters the
+// golang.org/x/crypto/scrypt package documentation recommends for
+// interactive logins.
+const (
+	fileStoreScryptN      = 1 << 15
+	fileStoreScryptR      = 8
+	fileStoreScryptP      = 1
+	fileStoreScryptKeyLen = 32
+	fileStoreSaltLen      = 16
+)
+
+// fileSessionStore stores one AES-GCM encrypted JSON file per
+// profile/mfaSerial pair under ~/.aws/vault-sessions/, keyed by a passphrase-derived key. It
+// exists for environments where neither the OS keychain nor the kernel
+// keyring is available.
+type fileSessionStore struct {
+	Dir        string
+	Passphrase []byte
+	Config     *Config
+}
+
+// NewFileSessionStore returns a SessionStore that keeps sessions in
+// ~/.aws/vault-sessions/, encrypted with a key derived from passphrase.
+func NewFileSessionStore(cfg *Config, passphrase []byte) (SessionStore, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("file session store requires a non-empty passphrase")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(home, ".aws", "vault-sessions")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &fileSessionStore{Dir: dir, Passphrase: passphrase, Config: cfg}, nil
+}
+
+type fileSessionPayload struct {
+	Profile     string          `json:"profile"`
+	MfaSerial   string          `json:"mfaSerial"`
+	Credentials sts.Credentials `json:"credentials"`
+}
+
+// fileSessionEnvelope is the on-disk format: the random salt used for key
+// derivation, the AES-GCM nonce, and the sealed payload.
+type fileSessionEnvelope struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fileNameSep joins the encoded profile and mfaSerial segments of a
+// session file name. base64Encoding's alphabet includes both "-" and "_",
+// so either would be ambiguous with the encoded data it's meant to
+// delimit; "." isn't part of the alphabet and can't appear inside a
+// segment.
+const fileNameSep = "."
+
+// path returns the file a session for profile/mfaSerial is stored under.
+// Both are folded into the name (rather than just profile) so a profile
+// used with more than one MFA serial gets one file per pair, matching the
+// SessionStore contract that Put replaces only the session for the same
+// profile/mfaSerial pair.
+func (s *fileSessionStore) path(profile string, mfaSerial string) string {
+	name := base64Encoding.EncodeToString([]byte(profile)) + fileNameSep +
+		base64Encoding.EncodeToString([]byte(mfaSerial)) + ".session"
+	return filepath.Join(s.Dir, name)
+}
+
+// profileFromFileName recovers the profile name encoded in a session file
+// name by path(), without needing to decrypt the file.
+func profileFromFileName(name string) (string, bool) {
+	name = strings.TrimSuffix(name, ".session")
+	encoded, _, ok := strings.Cut(name, fileNameSep)
+	if !ok {
+		return "", false
+	}
+	profile, err := base64Encoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	return string(profile), true
+}
+
+func (s *fileSessionStore) deriveKey(salt []byte) ([]byte, error) {
+	return scrypt.Key(s.Passphrase, salt, fileStoreScryptN, fileStoreScryptR, fileStoreScryptP, fileStoreScryptKeyLen)
+}
+
+func (s *fileSessionStore) seal(payload fileSessionPayload) ([]byte, error) {
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, fileStoreSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key, err := s.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	return json.Marshal(fileSessionEnvelope{Salt: salt, Nonce: nonce, Ciphertext: ciphertext})
+}
+
+func (s *fileSessionStore) open(data []byte) (fileSessionPayload, error) {
+	var envelope fileSessionEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fileSessionPayload{}, err
+	}
+
+	key, err := s.deriveKey(envelope.Salt)
+	if err != nil {
+		return fileSessionPayload{}, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return fileSessionPayload{}, err
+	}
+
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return fileSessionPayload{}, fmt.Errorf("decrypting session: %w", err)
+	}
+
+	var payload fileSessionPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return fileSessionPayload{}, err
+	}
+
+	return payload, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fileSessionStore) List() ([]KeyringSession, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []KeyringSession
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".session" {
+			continue
+		}
+
+		path := filepath.Join(s.Dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Error reading session file %s: %v", path, err)
+			continue
+		}
+
+		payload, err := s.open(data)
+		if err != nil {
+			log.Printf("Error decrypting session file %s: %v", path, err)
+			continue
+		}
+
+		profile, _ := s.Config.Profile(payload.Profile)
+		ks := KeyringSession{
+			Profile:    profile,
+			Key:        path,
+			Expiration: *payload.Credentials.Expiration,
+			MfaSerial:  payload.MfaSerial,
+		}
+
+		if ks.IsExpired() {
+			log.Printf("Session %q is obsolete, deleting", path)
+			if err := os.Remove(path); err != nil {
+				log.Printf("Error deleting session: %v", err)
+			}
+			continue
+		}
+
+		sessions = append(sessions, ks)
+	}
+
+	return sessions, nil
+}
+
+func (s *fileSessionStore) Get(profile string, mfaSerial string) (creds sts.Credentials, err error) {
+	path := s.path(profile, mfaSerial)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return creds, keyring.ErrKeyNotFound
+	} else if err != nil {
+		return creds, err
+	}
+
+	payload, err := s.open(data)
+	if err != nil {
+		return creds, err
+	}
+
+	if payload.Credentials.Expiration.Before(time.Now()) {
+		log.Printf("Session for %q is expired, deleting", profile)
+		if err := os.Remove(path); err != nil {
+			return creds, err
+		}
+		return creds, keyring.ErrKeyNotFound
+	}
+
+	return payload.Credentials, nil
+}
+
+func (s *fileSessionStore) Put(profile string, mfaSerial string, session sts.Credentials) error {
+	data, err := s.seal(fileSessionPayload{Profile: profile, MfaSerial: mfaSerial, Credentials: session})
+	if err != nil {
+		return err
+	}
+
+	path := s.path(profile, mfaSerial)
+	log.Printf("Writing session for %s to %s", profile, path)
+
+	return os.WriteFile(path, data, 0600)
+}
+
+func (s *fileSessionStore) Delete(profile string) (int, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		entryProfile, ok := profileFromFileName(entry.Name())
+		if !ok || entryProfile != profile {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.Dir, entry.Name())); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
@@ -0,0 +1,164 @@
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+func newTestFileSessionStore(t *testing.T) *fileSessionStore {
+	t.Helper()
+	store, err := NewFileSessionStore(&Config{}, []byte("test passphrase"))
+	if err != nil {
+		t.Fatalf("NewFileSessionStore() error = %v", err)
+	}
+	fs := store.(*fileSessionStore)
+	fs.Dir = t.TempDir()
+	return fs
+}
+
+func testCredentials(expiration time.Time) sts.Credentials {
+	return sts.Credentials{
+		AccessKeyId:     aws.String("AKIATEST"),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(expiration),
+	}
+}
+
+func TestFileSessionStore_PutGetRoundTrip(t *testing.T) {
+	s := newTestFileSessionStore(t)
+	creds := testCredentials(time.Now().Add(time.Hour))
+
+	if err := s.Put("my-profile", "arn:aws:iam::1234:mfa/user", creds); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, err := s.Get("my-profile", "arn:aws:iam::1234:mfa/user")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if *got.AccessKeyId != *creds.AccessKeyId {
+		t.Errorf("AccessKeyId = %q, want %q", *got.AccessKeyId, *creds.AccessKeyId)
+	}
+}
+
+func TestFileSessionStore_GetMissing(t *testing.T) {
+	s := newTestFileSessionStore(t)
+
+	if _, err := s.Get("missing-profile", ""); err != keyring.ErrKeyNotFound {
+		t.Errorf("Get() error = %v, want keyring.ErrKeyNotFound", err)
+	}
+}
+
+func TestFileSessionStore_DistinctMfaSerialsDontClobber(t *testing.T) {
+	s := newTestFileSessionStore(t)
+
+	credsA := testCredentials(time.Now().Add(time.Hour))
+	credsB := testCredentials(time.Now().Add(2 * time.Hour))
+
+	if err := s.Put("shared-profile", "mfa-a", credsA); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("shared-profile", "mfa-b", credsB); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	gotA, err := s.Get("shared-profile", "mfa-a")
+	if err != nil {
+		t.Fatalf("Get(mfa-a) error = %v", err)
+	}
+	if !gotA.Expiration.Equal(*credsA.Expiration) {
+		t.Errorf("Get(mfa-a) expiration = %v, want %v", gotA.Expiration, *credsA.Expiration)
+	}
+
+	gotB, err := s.Get("shared-profile", "mfa-b")
+	if err != nil {
+		t.Fatalf("Get(mfa-b) error = %v", err)
+	}
+	if !gotB.Expiration.Equal(*credsB.Expiration) {
+		t.Errorf("Get(mfa-b) expiration = %v, want %v", gotB.Expiration, *credsB.Expiration)
+	}
+}
+
+func TestFileSessionStore_Delete(t *testing.T) {
+	s := newTestFileSessionStore(t)
+
+	if err := s.Put("shared-profile", "mfa-a", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("shared-profile", "mfa-b", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("other-profile", "", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	n, err := s.Delete("shared-profile")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Delete() = %d, want 2", n)
+	}
+
+	if _, err := s.Get("shared-profile", "mfa-a"); err != keyring.ErrKeyNotFound {
+		t.Errorf("Get(mfa-a) after delete error = %v, want keyring.ErrKeyNotFound", err)
+	}
+	if _, err := s.Get("other-profile", ""); err != nil {
+		t.Errorf("Get(other-profile) after delete error = %v, want nil", err)
+	}
+}
+
+// TestFileSessionStore_DeleteProfileContainingEncodedSeparator guards
+// against profileFromFileName splitting inside the base64-encoded profile
+// segment: base64Encoding's alphabet includes "_", so a separator that
+// can also appear in the encoding would let Delete silently miss a file.
+func TestFileSessionStore_DeleteProfileContainingEncodedSeparator(t *testing.T) {
+	s := newTestFileSessionStore(t)
+
+	if err := s.Put("ab?", "", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	n, err := s.Delete("ab?")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Delete() = %d, want 1", n)
+	}
+
+	if _, err := s.Get("ab?", ""); err != keyring.ErrKeyNotFound {
+		t.Errorf("Get() after delete error = %v, want keyring.ErrKeyNotFound", err)
+	}
+}
+
+func TestFileSessionStore_ListPrunesExpired(t *testing.T) {
+	s := newTestFileSessionStore(t)
+
+	if err := s.Put("live-profile", "", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("expired-profile", "", testCredentials(time.Now().Add(-time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	sessions, err := s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("List() returned %d sessions, want 1", len(sessions))
+	}
+	if sessions[0].Profile.Name != "live-profile" {
+		t.Errorf("List()[0].Profile.Name = %q, want %q", sessions[0].Profile.Name, "live-profile")
+	}
+
+	if _, err := s.Get("expired-profile", ""); err != keyring.ErrKeyNotFound {
+		t.Errorf("Get(expired-profile) error = %v, want keyring.ErrKeyNotFound", err)
+	}
+}
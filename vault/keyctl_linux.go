@@ -0,0 +1,211 @@
+//go:build linux
+
+package vault
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/keyring"
+	"golang.org/x/sys/unix"
+)
+
+// KeyCtlScope selects which kernel keyring new session keys are linked into.
+type KeyCtlScope string
+
+const (
+	KeyCtlScopeUser    KeyCtlScope = "user"
+	KeyCtlScopeSession KeyCtlScope = "session"
+	KeyCtlScopeProcess KeyCtlScope = "process"
+	KeyCtlScopeThread  KeyCtlScope = "thread"
+)
+
+// KeyCtlPerm is the permission mask applied to keys via KEYCTL_SETPERM after
+// they're added to the keyring. See keyctl(2) for the bit layout.
+type KeyCtlPerm = uint32
+
+// ErrKeyCtlKeyringNotLinked is returned when the configured scope's keyring
+// isn't reachable from the calling process's session keyring.
+var ErrKeyCtlKeyringNotLinked = errors.New("keyctl: target keyring is not linked into the session keyring")
+
+func (s KeyCtlScope) id() (int, error) {
+	switch s {
+	case KeyCtlScopeUser:
+		return unix.KEY_SPEC_USER_KEYRING, nil
+	case KeyCtlScopeSession:
+		return unix.KEY_SPEC_SESSION_KEYRING, nil
+	case KeyCtlScopeProcess:
+		return unix.KEY_SPEC_PROCESS_KEYRING, nil
+	case KeyCtlScopeThread:
+		return unix.KEY_SPEC_THREAD_KEYRING, nil
+	default:
+		return 0, fmt.Errorf("keyctl: unknown scope %q", s)
+	}
+}
+
+// keyctlKeyring stores items in the Linux kernel keyring, so sessions can be
+// shared between processes belonging to the same user/session without
+// touching disk or the desktop keychain.
+type keyctlKeyring struct {
+	ringID int
+	perm   KeyCtlPerm
+}
+
+// NewKeyCtlKeyring returns a keyring.Keyring backed by the Linux kernel
+// keyring, with new keys linked into the keyring identified by scope and
+// restricted by perm.
+func NewKeyCtlKeyring(scope KeyCtlScope, perm KeyCtlPerm) (keyring.Keyring, error) {
+	ringID, err := scope.id()
+	if err != nil {
+		return nil, err
+	}
+	return &keyctlKeyring{ringID: ringID, perm: perm}, nil
+}
+
+// NewKeyCtlSessions returns a KeyringSessions backed by the Linux kernel
+// keyring, with new session keys linked into the keyring identified by
+// scope and restricted by perm.
+func NewKeyCtlSessions(cfg *Config, scope KeyCtlScope, perm KeyCtlPerm) (*KeyringSessions, error) {
+	k, err := NewKeyCtlKeyring(scope, perm)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyringSessions(k, cfg)
+}
+
+func (k *keyctlKeyring) Get(key string) (keyring.Item, error) {
+	id, err := unix.KeyctlSearch(k.ringID, "user", key, 0)
+	if err != nil {
+		if errors.Is(err, unix.ENOKEY) {
+			return keyring.Item{}, keyring.ErrKeyNotFound
+		}
+		if errors.Is(err, unix.EINVAL) {
+			return keyring.Item{}, ErrKeyCtlKeyringNotLinked
+		}
+		// EACCES means the keyring wasn't searchable (e.g. KeyCtlScope
+		// points at a keyring we don't have search permission on, or
+		// KeyCtlPerm is too restrictive) -- surface it rather than
+		// reporting a plain cache miss, so a misconfiguration doesn't
+		// just look like "no session cached".
+		return keyring.Item{}, err
+	}
+
+	data, err := keyctlRead(id)
+	if err != nil {
+		return keyring.Item{}, err
+	}
+
+	return keyring.Item{Key: key, Data: data}, nil
+}
+
+func (k *keyctlKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	return keyring.Metadata{}, keyring.ErrMetadataNeedsCredentials
+}
+
+func (k *keyctlKeyring) Set(item keyring.Item) error {
+	id, err := unix.AddKey("user", item.Key, item.Data, k.ringID)
+	if err != nil {
+		if errors.Is(err, unix.EINVAL) {
+			return ErrKeyCtlKeyringNotLinked
+		}
+		return err
+	}
+
+	if _, err := unix.KeyctlInt(unix.KEYCTL_SETPERM, id, int(k.perm), 0, 0); err != nil {
+		return fmt.Errorf("keyctl: setting permissions: %w", err)
+	}
+
+	return nil
+}
+
+func (k *keyctlKeyring) Remove(key string) error {
+	id, err := unix.KeyctlSearch(k.ringID, "user", key, 0)
+	if err != nil {
+		if errors.Is(err, unix.ENOKEY) {
+			return keyring.ErrKeyNotFound
+		}
+		return err
+	}
+
+	_, err = unix.KeyctlInt(unix.KEYCTL_UNLINK, id, k.ringID, 0, 0)
+	return err
+}
+
+func (k *keyctlKeyring) Keys() ([]string, error) {
+	data, err := keyctlRead(k.ringID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := parseKeyringListing(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		desc, err := unix.KeyctlString(unix.KEYCTL_DESCRIBE, id)
+		if err != nil {
+			continue
+		}
+		if key, ok := parseUserKeyDescription(desc); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// parseKeyringListing decodes the payload returned by reading a keyring
+// (as opposed to a key): a sequence of little-endian 32-bit key IDs.
+func parseKeyringListing(data []byte) ([]int, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("keyctl: unexpected keyring listing length %d", len(data))
+	}
+
+	ids := make([]int, 0, len(data)/4)
+	for i := 0; i < len(data); i += 4 {
+		ids = append(ids, int(int32(binary.LittleEndian.Uint32(data[i:i+4]))))
+	}
+
+	return ids, nil
+}
+
+// parseUserKeyDescription parses the "type;uid;gid;perm;description"
+// string returned by KEYCTL_DESCRIBE, returning the key's description
+// (the name passed to add_key) if it's a "user" type key.
+func parseUserKeyDescription(desc string) (string, bool) {
+	parts := strings.SplitN(desc, ";", 5)
+	if len(parts) != 5 || parts[0] != "user" {
+		return "", false
+	}
+	return parts[4], true
+}
+
+// keyctlRead reads a key's payload, growing the buffer as instructed by the
+// kernel when KEYCTL_READ reports the payload was larger than what we
+// passed in.
+func keyctlRead(id int) ([]byte, error) {
+	return keyctlReadWith(func(buf []byte) (int, error) {
+		return unix.KeyctlBuffer(unix.KEYCTL_READ, id, buf, 0)
+	})
+}
+
+// keyctlReadWith implements the grow-buffer loop used by keyctlRead, with
+// the actual read call factored out so the loop logic can be tested
+// without real kernel keys.
+func keyctlReadWith(read func(buf []byte) (int, error)) ([]byte, error) {
+	buf := make([]byte, 512)
+	for {
+		n, err := read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n <= len(buf) {
+			return buf[:n], nil
+		}
+		buf = make([]byte, n)
+	}
+}
@@ -0,0 +1,127 @@
+//go:build linux
+
+package vault
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestKeyCtlScope_id(t *testing.T) {
+	tests := []struct {
+		scope   KeyCtlScope
+		want    int
+		wantErr bool
+	}{
+		{KeyCtlScopeUser, unix.KEY_SPEC_USER_KEYRING, false},
+		{KeyCtlScopeSession, unix.KEY_SPEC_SESSION_KEYRING, false},
+		{KeyCtlScopeProcess, unix.KEY_SPEC_PROCESS_KEYRING, false},
+		{KeyCtlScopeThread, unix.KEY_SPEC_THREAD_KEYRING, false},
+		{KeyCtlScope("bogus"), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.scope.id()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q.id() error = %v, wantErr %v", tt.scope, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%q.id() = %d, want %d", tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeyringListing(t *testing.T) {
+	encode := func(ids ...int32) []byte {
+		buf := make([]byte, 4*len(ids))
+		for i, id := range ids {
+			binary.LittleEndian.PutUint32(buf[i*4:], uint32(id))
+		}
+		return buf
+	}
+
+	ids, err := parseKeyringListing(encode(123, 456, -1))
+	if err != nil {
+		t.Fatalf("parseKeyringListing() error = %v", err)
+	}
+	want := []int{123, 456, -1}
+	if len(ids) != len(want) {
+		t.Fatalf("parseKeyringListing() = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("parseKeyringListing()[%d] = %d, want %d", i, ids[i], want[i])
+		}
+	}
+
+	if _, err := parseKeyringListing([]byte{1, 2, 3}); err == nil {
+		t.Error("parseKeyringListing() with misaligned data error = nil, want non-nil")
+	}
+}
+
+func TestParseUserKeyDescription(t *testing.T) {
+	tests := []struct {
+		desc   string
+		want   string
+		wantOK bool
+	}{
+		{"user;1000;1000;3f010000;session,bXktcHJvZmlsZQ,,1785123619", "session,bXktcHJvZmlsZQ,,1785123619", true},
+		{"keyring;1000;1000;3f010000;_ses", "", false},
+		{"user;1000;1000;3f010000", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseUserKeyDescription(tt.desc)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseUserKeyDescription(%q) = (%q, %v), want (%q, %v)", tt.desc, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestKeyctlReadWith_GrowsBufferOnTruncation(t *testing.T) {
+	full := make([]byte, 1024)
+	for i := range full {
+		full[i] = byte(i)
+	}
+
+	calls := 0
+	read := func(buf []byte) (int, error) {
+		calls++
+		if len(buf) < len(full) {
+			// Simulate the kernel reporting the true size on truncation,
+			// without writing more than fits.
+			return len(full), nil
+		}
+		return copy(buf, full), nil
+	}
+
+	got, err := keyctlReadWith(read)
+	if err != nil {
+		t.Fatalf("keyctlReadWith() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("read called %d times, want 2 (grow once)", calls)
+	}
+	if len(got) != len(full) {
+		t.Fatalf("keyctlReadWith() returned %d bytes, want %d", len(got), len(full))
+	}
+	for i := range full {
+		if got[i] != full[i] {
+			t.Fatalf("keyctlReadWith() byte %d = %d, want %d", i, got[i], full[i])
+		}
+	}
+}
+
+func TestKeyctlReadWith_ReturnsFirstCallError(t *testing.T) {
+	wantErr := errors.New("boom")
+	_, err := keyctlReadWith(func(buf []byte) (int, error) {
+		return 0, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("keyctlReadWith() error = %v, want %v", err, wantErr)
+	}
+}
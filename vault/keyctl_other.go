@@ -0,0 +1,31 @@
+//go:build !linux
+
+package vault
+
+import (
+	"errors"
+
+	"github.com/99designs/keyring"
+)
+
+// KeyCtlScope selects which kernel keyring new session keys are linked into.
+// It only has meaning on Linux.
+type KeyCtlScope string
+
+// KeyCtlPerm is the permission mask applied to keys added to the keyring.
+// It only has meaning on Linux.
+type KeyCtlPerm = uint32
+
+var errKeyCtlUnsupported = errors.New("keyctl: not supported on this platform")
+
+// NewKeyCtlKeyring always returns an error outside of Linux, where the
+// kernel keyring doesn't exist.
+func NewKeyCtlKeyring(scope KeyCtlScope, perm KeyCtlPerm) (keyring.Keyring, error) {
+	return nil, errKeyCtlUnsupported
+}
+
+// NewKeyCtlSessions always returns an error outside of Linux, where the
+// kernel keyring doesn't exist.
+func NewKeyCtlSessions(cfg *Config, scope KeyCtlScope, perm KeyCtlPerm) (*KeyringSessions, error) {
+	return nil, errKeyCtlUnsupported
+}
@@ -0,0 +1,178 @@
+package vault
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// keyringSessionStore is the original SessionStore implementation, backed
+// by a keyring.Keyring (OS keychain, encrypted file, kernel keyring, etc.
+// depending on how it was opened).
+type keyringSessionStore struct {
+	Keyring keyring.Keyring
+	Config  *Config
+}
+
+func (s *keyringSessionStore) List() ([]KeyringSession, error) {
+	log.Printf("Looking up all keys in keyring")
+	keys, err := s.Keyring.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []KeyringSession
+
+	for _, k := range keys {
+		if !IsSessionKey(k) {
+			continue
+		}
+
+		ks, err := parseSessionKey(k, s.Config)
+		if err != nil {
+			// Not the current format; it may be a session cached by an
+			// older aws-vault under one of the legacy key formats.
+			ks, err = parseLegacySessionKey(k, s.Config)
+			if err != nil {
+				log.Printf("Session %s is obsolete, attempting deleting", k)
+				if err := s.Keyring.Remove(k); err != nil {
+					log.Printf("Error deleting session: %v", err)
+				}
+				continue
+			}
+
+			if ks.IsExpired() {
+				log.Printf("Session %s is obsolete, attempting deleting", k)
+				if err := s.Keyring.Remove(k); err != nil {
+					log.Printf("Error deleting session: %v", err)
+				}
+				continue
+			}
+
+			ks, err = s.migrateLegacyKey(k, ks)
+			if err != nil {
+				log.Printf("Error migrating legacy session key %q: %v", k, err)
+				continue
+			}
+
+			sessions = append(sessions, ks)
+			continue
+		}
+
+		if ks.IsExpired() {
+			log.Printf("Session %s is obsolete, attempting deleting", k)
+			if err := s.Keyring.Remove(k); err != nil {
+				log.Printf("Error deleting session: %v", err)
+			}
+			continue
+		}
+
+		sessions = append(sessions, ks)
+	}
+
+	return sessions, nil
+}
+
+// migrateLegacyKey renames a session cached under a legacy key format to
+// the current formatSessionKey scheme, preserving its credentials so the
+// user isn't forced through another MFA prompt just because the key
+// format changed.
+func (s *keyringSessionStore) migrateLegacyKey(oldKey string, ks KeyringSession) (KeyringSession, error) {
+	item, err := s.Keyring.Get(oldKey)
+	if err != nil {
+		return ks, err
+	}
+
+	newKey := formatSessionKey(ks.Profile.Name, ks.MfaSerial, &ks.Expiration)
+	item.Key = newKey
+
+	if err := s.Keyring.Set(item); err != nil {
+		return ks, err
+	}
+	if err := s.Keyring.Remove(oldKey); err != nil {
+		return ks, err
+	}
+
+	log.Printf("Migrated legacy session key %q to %q", oldKey, newKey)
+	ks.Key = newKey
+
+	return ks, nil
+}
+
+func (s *keyringSessionStore) Get(profile string, mfaSerial string) (creds sts.Credentials, err error) {
+	log.Printf("Looking for sessions for %s", profile)
+	sessions, err := s.List()
+	if err != nil {
+		return creds, err
+	}
+
+	for _, session := range sessions {
+		if session.Profile.Name == profile && session.MfaSerial == mfaSerial {
+			item, err := s.Keyring.Get(session.Key)
+			if err != nil {
+				return creds, err
+			}
+
+			if err = json.Unmarshal(item.Data, &creds); err != nil {
+				return creds, err
+			}
+
+			// double check the actual expiry time
+			if creds.Expiration.Before(time.Now()) {
+				log.Printf("Session %q is expired, deleting", session.Key)
+				if err = s.Keyring.Remove(session.Key); err != nil {
+					return creds, err
+				}
+				return sts.Credentials{}, keyring.ErrKeyNotFound
+			}
+
+			// success!
+			return creds, nil
+		}
+	}
+
+	return creds, keyring.ErrKeyNotFound
+}
+
+func (s *keyringSessionStore) Put(profile string, mfaSerial string, session sts.Credentials) error {
+	bytes, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	key := formatSessionKey(profile, mfaSerial, session.Expiration)
+	log.Printf("Writing session for %s to keyring: %q", profile, key)
+
+	return s.Keyring.Set(keyring.Item{
+		Key:         key,
+		Label:       "aws-vault session for " + profile,
+		Description: "aws-vault session for " + profile,
+		Data:        bytes,
+
+		// specific Keychain settings
+		KeychainNotTrustApplication: false,
+	})
+}
+
+func (s *keyringSessionStore) Delete(profile string) (n int, err error) {
+	log.Printf("Looking for sessions for %s", profile)
+	sessions, err := s.List()
+	if err != nil {
+		return n, err
+	}
+
+	for _, session := range sessions {
+		if session.Profile.Name == profile {
+			log.Printf("Session %q matches profile %q", session.Key, profile)
+			if err = s.Keyring.Remove(session.Key); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+
+	return
+}
@@ -0,0 +1,149 @@
+package vault
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// memKeyring is a minimal in-memory keyring.Keyring for tests.
+type memKeyring struct {
+	items map[string]keyring.Item
+}
+
+func newMemKeyring() *memKeyring {
+	return &memKeyring{items: map[string]keyring.Item{}}
+}
+
+func (k *memKeyring) Get(key string) (keyring.Item, error) {
+	item, ok := k.items[key]
+	if !ok {
+		return keyring.Item{}, keyring.ErrKeyNotFound
+	}
+	return item, nil
+}
+
+func (k *memKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	return keyring.Metadata{}, keyring.ErrMetadataNeedsCredentials
+}
+
+func (k *memKeyring) Set(item keyring.Item) error {
+	k.items[item.Key] = item
+	return nil
+}
+
+func (k *memKeyring) Remove(key string) error {
+	if _, ok := k.items[key]; !ok {
+		return keyring.ErrKeyNotFound
+	}
+	delete(k.items, key)
+	return nil
+}
+
+func (k *memKeyring) Keys() ([]string, error) {
+	keys := make([]string, 0, len(k.items))
+	for key := range k.items {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func TestParseLegacySessionKey_ColonDelimited(t *testing.T) {
+	cfg := &Config{}
+	expiration := time.Now().Add(time.Hour).Truncate(time.Second)
+	key := "session:my-profile:my-mfa-serial:" + strconv.FormatInt(expiration.Unix(), 10)
+
+	ks, err := parseLegacySessionKey(key, cfg)
+	if err != nil {
+		t.Fatalf("parseLegacySessionKey() error = %v", err)
+	}
+	if ks.Profile.Name != "my-profile" {
+		t.Errorf("Profile.Name = %q, want %q", ks.Profile.Name, "my-profile")
+	}
+	if ks.MfaSerial != "my-mfa-serial" {
+		t.Errorf("MfaSerial = %q, want %q", ks.MfaSerial, "my-mfa-serial")
+	}
+	if !ks.Expiration.Equal(expiration) {
+		t.Errorf("Expiration = %v, want %v", ks.Expiration, expiration)
+	}
+}
+
+func TestParseLegacySessionKey_NamedForm(t *testing.T) {
+	cfg := &Config{}
+	expiration := time.Now().Add(time.Hour).Truncate(time.Second)
+	key := "my-profile session (" + strconv.FormatInt(expiration.Unix(), 10) + ")"
+
+	ks, err := parseLegacySessionKey(key, cfg)
+	if err != nil {
+		t.Fatalf("parseLegacySessionKey() error = %v", err)
+	}
+	if ks.Profile.Name != "my-profile" {
+		t.Errorf("Profile.Name = %q, want %q", ks.Profile.Name, "my-profile")
+	}
+	if ks.MfaSerial != "" {
+		t.Errorf("MfaSerial = %q, want empty", ks.MfaSerial)
+	}
+	if !ks.Expiration.Equal(expiration) {
+		t.Errorf("Expiration = %v, want %v", ks.Expiration, expiration)
+	}
+}
+
+func TestParseLegacySessionKey_Invalid(t *testing.T) {
+	if _, err := parseLegacySessionKey("not-a-session-key", &Config{}); err == nil {
+		t.Error("parseLegacySessionKey() error = nil, want non-nil")
+	}
+}
+
+func TestKeyringSessionStore_List_MigratesLegacyKey(t *testing.T) {
+	kr := newMemKeyring()
+	cfg := &Config{}
+	store := &keyringSessionStore{Keyring: kr, Config: cfg}
+
+	expiration := time.Now().Add(time.Hour)
+	oldKey := "my-profile session (" + strconv.FormatInt(expiration.Unix(), 10) + ")"
+	if err := kr.Set(keyring.Item{Key: oldKey, Data: []byte(`{"SecretAccessKey":"s"}`)}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	sessions, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("List() returned %d sessions, want 1", len(sessions))
+	}
+
+	newKey := formatSessionKey("my-profile", "", &sessions[0].Expiration)
+	if sessions[0].Key != newKey {
+		t.Errorf("sessions[0].Key = %q, want %q", sessions[0].Key, newKey)
+	}
+
+	if _, err := kr.Get(oldKey); err != keyring.ErrKeyNotFound {
+		t.Errorf("old key still present after migration, Get() error = %v", err)
+	}
+	if _, err := kr.Get(newKey); err != nil {
+		t.Errorf("new key missing after migration, Get() error = %v", err)
+	}
+}
+
+func TestKeyringSessionStore_List_DeletesUnparseableKey(t *testing.T) {
+	kr := newMemKeyring()
+	store := &keyringSessionStore{Keyring: kr, Config: &Config{}}
+
+	if err := kr.Set(keyring.Item{Key: "session,not-valid-base64!!,,123", Data: []byte("{}")}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	sessions, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Fatalf("List() returned %d sessions, want 0", len(sessions))
+	}
+	if keys, _ := kr.Keys(); len(keys) != 0 {
+		t.Errorf("unparseable key was not removed, remaining keys = %v", keys)
+	}
+}
@@ -0,0 +1,114 @@
+package vault
+
+import (
+	"sync"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// memorySession is a single cached session held by memorySessionStore.
+type memorySession struct {
+	MfaSerial   string
+	Credentials sts.Credentials
+}
+
+// memorySessionStore is an in-process SessionStore with no persistence,
+// intended for tests and short-lived automation that shouldn't touch the
+// OS keychain or disk.
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	Config   *Config
+}
+
+// NewMemorySessionStore returns a SessionStore that keeps sessions only
+// for the lifetime of the process.
+func NewMemorySessionStore(cfg *Config) SessionStore {
+	return &memorySessionStore{
+		sessions: make(map[string]memorySession),
+		Config:   cfg,
+	}
+}
+
+func memorySessionKey(profile string, mfaSerial string) string {
+	return profile + "\x00" + mfaSerial
+}
+
+func (s *memorySessionStore) List() ([]KeyringSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sessions []KeyringSession
+
+	for key, session := range s.sessions {
+		ks := KeyringSession{
+			MfaSerial:  session.MfaSerial,
+			Expiration: *session.Credentials.Expiration,
+		}
+		ks.Profile, _ = s.Config.Profile(splitMemorySessionProfile(key))
+
+		if ks.IsExpired() {
+			delete(s.sessions, key)
+			continue
+		}
+
+		sessions = append(sessions, ks)
+	}
+
+	return sessions, nil
+}
+
+func splitMemorySessionProfile(key string) string {
+	for i := 0; i < len(key); i++ {
+		if key[i] == 0 {
+			return key[:i]
+		}
+	}
+	return key
+}
+
+func (s *memorySessionStore) Get(profile string, mfaSerial string) (sts.Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[memorySessionKey(profile, mfaSerial)]
+	if !ok {
+		return sts.Credentials{}, keyring.ErrKeyNotFound
+	}
+
+	if session.Credentials.Expiration.Before(time.Now()) {
+		delete(s.sessions, memorySessionKey(profile, mfaSerial))
+		return sts.Credentials{}, keyring.ErrKeyNotFound
+	}
+
+	return session.Credentials, nil
+}
+
+func (s *memorySessionStore) Put(profile string, mfaSerial string, session sts.Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[memorySessionKey(profile, mfaSerial)] = memorySession{
+		MfaSerial:   mfaSerial,
+		Credentials: session,
+	}
+
+	return nil
+}
+
+func (s *memorySessionStore) Delete(profile string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for key := range s.sessions {
+		if splitMemorySessionProfile(key) == profile {
+			delete(s.sessions, key)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,103 @@
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+func TestMemorySessionStore_PutGetRoundTrip(t *testing.T) {
+	s := NewMemorySessionStore(&Config{})
+	creds := testCredentials(time.Now().Add(time.Hour))
+
+	if err := s.Put("my-profile", "my-mfa-serial", creds); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, err := s.Get("my-profile", "my-mfa-serial")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if *got.AccessKeyId != *creds.AccessKeyId {
+		t.Errorf("AccessKeyId = %q, want %q", *got.AccessKeyId, *creds.AccessKeyId)
+	}
+}
+
+func TestMemorySessionStore_GetMissing(t *testing.T) {
+	s := NewMemorySessionStore(&Config{})
+
+	if _, err := s.Get("missing-profile", ""); err != keyring.ErrKeyNotFound {
+		t.Errorf("Get() error = %v, want keyring.ErrKeyNotFound", err)
+	}
+}
+
+func TestMemorySessionStore_DistinctMfaSerialsDontClobber(t *testing.T) {
+	s := NewMemorySessionStore(&Config{})
+
+	credsA := testCredentials(time.Now().Add(time.Hour))
+	credsB := testCredentials(time.Now().Add(2 * time.Hour))
+
+	if err := s.Put("shared-profile", "mfa-a", credsA); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("shared-profile", "mfa-b", credsB); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	gotA, err := s.Get("shared-profile", "mfa-a")
+	if err != nil {
+		t.Fatalf("Get(mfa-a) error = %v", err)
+	}
+	if !gotA.Expiration.Equal(*credsA.Expiration) {
+		t.Errorf("Get(mfa-a) expiration = %v, want %v", gotA.Expiration, *credsA.Expiration)
+	}
+}
+
+func TestMemorySessionStore_Delete(t *testing.T) {
+	s := NewMemorySessionStore(&Config{})
+
+	if err := s.Put("shared-profile", "mfa-a", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("shared-profile", "mfa-b", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("other-profile", "", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	n, err := s.Delete("shared-profile")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Delete() = %d, want 2", n)
+	}
+
+	if _, err := s.Get("other-profile", ""); err != nil {
+		t.Errorf("Get(other-profile) after delete error = %v, want nil", err)
+	}
+}
+
+func TestMemorySessionStore_ListPrunesExpired(t *testing.T) {
+	s := NewMemorySessionStore(&Config{})
+
+	if err := s.Put("live-profile", "", testCredentials(time.Now().Add(time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("expired-profile", "", testCredentials(time.Now().Add(-time.Hour))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	sessions, err := s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("List() returned %d sessions, want 1", len(sessions))
+	}
+	if sessions[0].Profile.Name != "live-profile" {
+		t.Errorf("List()[0].Profile.Name = %q, want %q", sessions[0].Profile.Name, "live-profile")
+	}
+}
@@ -0,0 +1,125 @@
+package vault
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// Default pacing for a Refresher that doesn't set LeadTime/Interval
+// explicitly.
+const (
+	DefaultRefreshLeadTime = 5 * time.Minute
+	DefaultRefreshInterval = time.Minute
+)
+
+// RenewFunc renews a session for profile, using the same
+// GetSessionToken/AssumeRole path taken on a cache miss.
+type RenewFunc func(profile string, mfaSerial string) (sts.Credentials, error)
+
+// Refresher pre-renews cached STS sessions before they expire, so
+// interactive calls like `aws-vault exec` never block on STS. It's driven
+// either by a goroutine inside a long-lived exec server or by the
+// `aws-vault refresh` daemon command.
+type Refresher struct {
+	Sessions *KeyringSessions
+	Renew    RenewFunc
+
+	// LeadTime is how long before expiration a session is renewed.
+	// Defaults to DefaultRefreshLeadTime.
+	LeadTime time.Duration
+
+	// Interval is how often Sessions() is scanned for sessions due for
+	// renewal. Defaults to DefaultRefreshInterval.
+	Interval time.Duration
+
+	// MFAAvailable reports whether a cached MFA token provider is
+	// registered for serial. Sessions that require MFA are skipped, not
+	// deleted, when this returns false or is nil, since renewing them
+	// would otherwise block on an interactive MFA prompt.
+	MFAAvailable func(serial string) bool
+}
+
+// NewRefresher returns a Refresher that renews sessions cached in sessions
+// using renew, at the default lead time and interval.
+func NewRefresher(sessions *KeyringSessions, renew RenewFunc) *Refresher {
+	return &Refresher{
+		Sessions: sessions,
+		Renew:    renew,
+		LeadTime: DefaultRefreshLeadTime,
+		Interval: DefaultRefreshInterval,
+	}
+}
+
+// Run scans for sessions due for renewal immediately, then again every
+// Interval, until ctx is done.
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval())
+	defer ticker.Stop()
+
+	for {
+		r.refreshDue()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Refresher) refreshDue() {
+	sessions, err := r.Sessions.Sessions()
+	if err != nil {
+		log.Printf("refresher: listing sessions: %v", err)
+		return
+	}
+
+	for _, session := range sessions {
+		r.maybeRefresh(session)
+	}
+}
+
+func (r *Refresher) maybeRefresh(session KeyringSession) {
+	if time.Until(session.Expiration) > r.leadTime() {
+		return
+	}
+
+	if session.MfaSerial != "" && !r.mfaAvailable(session.MfaSerial) {
+		log.Printf("session %q requires MFA and no cached token provider is registered, skipping refresh", session.Key)
+		return
+	}
+
+	creds, err := r.Renew(session.Profile.Name, session.MfaSerial)
+	if err != nil {
+		log.Printf("session %q failed to refresh: %v", session.Key, err)
+		return
+	}
+
+	if err := r.Sessions.Store(session.Profile.Name, session.MfaSerial, creds); err != nil {
+		log.Printf("session %q failed to store refreshed credentials: %v", session.Key, err)
+		return
+	}
+
+	log.Printf("session %q refreshed, new expiry %s", session.Key, creds.Expiration)
+}
+
+func (r *Refresher) mfaAvailable(serial string) bool {
+	return r.MFAAvailable != nil && r.MFAAvailable(serial)
+}
+
+func (r *Refresher) leadTime() time.Duration {
+	if r.LeadTime <= 0 {
+		return DefaultRefreshLeadTime
+	}
+	return r.LeadTime
+}
+
+func (r *Refresher) interval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultRefreshInterval
+	}
+	return r.Interval
+}
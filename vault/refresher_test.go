@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// fakeSessionStore records Put calls so tests can assert whether a
+// refresh actually persisted new credentials.
+type fakeSessionStore struct {
+	puts []struct {
+		profile   string
+		mfaSerial string
+		session   sts.Credentials
+	}
+}
+
+func (s *fakeSessionStore) Get(profile string, mfaSerial string) (sts.Credentials, error) {
+	return sts.Credentials{}, nil
+}
+
+func (s *fakeSessionStore) Put(profile string, mfaSerial string, session sts.Credentials) error {
+	s.puts = append(s.puts, struct {
+		profile   string
+		mfaSerial string
+		session   sts.Credentials
+	}{profile, mfaSerial, session})
+	return nil
+}
+
+func (s *fakeSessionStore) List() ([]KeyringSession, error) { return nil, nil }
+
+func (s *fakeSessionStore) Delete(profile string) (int, error) { return 0, nil }
+
+func newTestRefresher(store *fakeSessionStore, renew RenewFunc) *Refresher {
+	return &Refresher{
+		Sessions: &KeyringSessions{Backend: store},
+		Renew:    renew,
+		LeadTime: 10 * time.Minute,
+		Interval: time.Minute,
+	}
+}
+
+func TestRefresher_SkipsSessionNotYetDueForRenewal(t *testing.T) {
+	store := &fakeSessionStore{}
+	renewed := false
+	r := newTestRefresher(store, func(profile, mfaSerial string) (sts.Credentials, error) {
+		renewed = true
+		return sts.Credentials{}, nil
+	})
+
+	session := KeyringSession{
+		Profile:    Profile{Name: "my-profile"},
+		Expiration: time.Now().Add(time.Hour),
+	}
+	r.maybeRefresh(session)
+
+	if renewed {
+		t.Error("maybeRefresh() renewed a session with plenty of time left")
+	}
+	if len(store.puts) != 0 {
+		t.Errorf("maybeRefresh() stored %d sessions, want 0", len(store.puts))
+	}
+}
+
+func TestRefresher_RenewsSessionWithinLeadTime(t *testing.T) {
+	store := &fakeSessionStore{}
+	newExpiration := time.Now().Add(time.Hour)
+	r := newTestRefresher(store, func(profile, mfaSerial string) (sts.Credentials, error) {
+		if profile != "my-profile" {
+			t.Errorf("Renew() profile = %q, want %q", profile, "my-profile")
+		}
+		return sts.Credentials{
+			AccessKeyId: aws.String("renewed"),
+			Expiration:  aws.Time(newExpiration),
+		}, nil
+	})
+
+	session := KeyringSession{
+		Profile:    Profile{Name: "my-profile"},
+		Expiration: time.Now().Add(time.Minute),
+	}
+	r.maybeRefresh(session)
+
+	if len(store.puts) != 1 {
+		t.Fatalf("maybeRefresh() stored %d sessions, want 1", len(store.puts))
+	}
+	if *store.puts[0].session.AccessKeyId != "renewed" {
+		t.Errorf("stored AccessKeyId = %q, want %q", *store.puts[0].session.AccessKeyId, "renewed")
+	}
+}
+
+func TestRefresher_SkipsMFASessionWithoutTokenProvider(t *testing.T) {
+	store := &fakeSessionStore{}
+	renewed := false
+	r := newTestRefresher(store, func(profile, mfaSerial string) (sts.Credentials, error) {
+		renewed = true
+		return sts.Credentials{}, nil
+	})
+	r.MFAAvailable = func(serial string) bool { return false }
+
+	session := KeyringSession{
+		Profile:    Profile{Name: "my-profile"},
+		MfaSerial:  "arn:aws:iam::1234:mfa/user",
+		Expiration: time.Now().Add(time.Minute),
+	}
+	r.maybeRefresh(session)
+
+	if renewed {
+		t.Error("maybeRefresh() renewed an MFA session with no cached token provider")
+	}
+	if len(store.puts) != 0 {
+		t.Errorf("maybeRefresh() stored %d sessions, want 0", len(store.puts))
+	}
+}
+
+func TestRefresher_RenewsMFASessionWhenProviderAvailable(t *testing.T) {
+	store := &fakeSessionStore{}
+	renewed := false
+	r := newTestRefresher(store, func(profile, mfaSerial string) (sts.Credentials, error) {
+		renewed = true
+		return sts.Credentials{
+			AccessKeyId: aws.String("renewed"),
+			Expiration:  aws.Time(time.Now().Add(time.Hour)),
+		}, nil
+	})
+	r.MFAAvailable = func(serial string) bool { return true }
+
+	session := KeyringSession{
+		Profile:    Profile{Name: "my-profile"},
+		MfaSerial:  "arn:aws:iam::1234:mfa/user",
+		Expiration: time.Now().Add(time.Minute),
+	}
+	r.maybeRefresh(session)
+
+	if !renewed {
+		t.Error("maybeRefresh() did not renew an MFA session with a cached token provider available")
+	}
+	if len(store.puts) != 1 {
+		t.Errorf("maybeRefresh() stored %d sessions, want 1", len(store.puts))
+	}
+}
+
+func TestRefresher_DefaultsLeadTimeAndInterval(t *testing.T) {
+	r := &Refresher{}
+	if r.leadTime() != DefaultRefreshLeadTime {
+		t.Errorf("leadTime() = %v, want %v", r.leadTime(), DefaultRefreshLeadTime)
+	}
+	if r.interval() != DefaultRefreshInterval {
+		t.Errorf("interval() = %v, want %v", r.interval(), DefaultRefreshInterval)
+	}
+}
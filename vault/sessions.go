@@ -2,7 +2,6 @@ package vault
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -59,6 +58,42 @@ func parseSessionKey(key string, conf *Config) (KeyringSession, error) {
 	}, nil
 }
 
+// parseLegacySessionKey parses a session key in one of the formats used
+// before formatSessionKey switched to the comma-delimited, base64-encoded
+// scheme. It's only consulted when parseSessionKey fails, so a cached
+// session written by an older aws-vault keeps working instead of being
+// treated as unparseable and deleted.
+func parseLegacySessionKey(key string, conf *Config) (KeyringSession, error) {
+	if matches := oldSessionKeyPatterns[0].FindStringSubmatch(key); matches != nil {
+		tsInt, err := strconv.ParseInt(matches[3], 10, 64)
+		if err != nil {
+			return KeyringSession{}, err
+		}
+		profile, _ := conf.Profile(matches[1])
+		return KeyringSession{
+			Profile:    profile,
+			Key:        key,
+			Expiration: time.Unix(tsInt, 0),
+			MfaSerial:  matches[2],
+		}, nil
+	}
+
+	if matches := oldSessionKeyPatterns[1].FindStringSubmatch(key); matches != nil {
+		tsInt, err := strconv.ParseInt(matches[2], 10, 64)
+		if err != nil {
+			return KeyringSession{}, err
+		}
+		profile, _ := conf.Profile(matches[1])
+		return KeyringSession{
+			Profile:    profile,
+			Key:        key,
+			Expiration: time.Unix(tsInt, 0),
+		}, nil
+	}
+
+	return KeyringSession{}, errors.New("failed to parse legacy session name")
+}
+
 func formatSessionKey(profile string, mfaSerial string, expiration *time.Time) string {
 	return fmt.Sprintf(
 		"session,%s,%s,%d",
@@ -80,118 +115,38 @@ func (ks KeyringSession) IsExpired() bool {
 	return time.Now().After(ks.Expiration)
 }
 
+// KeyringSessions caches STS sessions in a pluggable SessionStore. The name
+// predates the SessionStore abstraction and has stuck around for backwards
+// compatibility; the keyring is just the default backend.
 type KeyringSessions struct {
-	Keyring keyring.Keyring
+	Backend SessionStore
 	Config  *Config
 }
 
+// NewKeyringSessions returns a KeyringSessions backed directly by a
+// keyring.Keyring, preserving the historical behaviour of this package.
 func NewKeyringSessions(k keyring.Keyring, cfg *Config) (*KeyringSessions, error) {
 	return &KeyringSessions{
-		Keyring: k,
+		Backend: &keyringSessionStore{Keyring: k, Config: cfg},
 		Config:  cfg,
 	}, nil
 }
 
 func (s *KeyringSessions) Sessions() ([]KeyringSession, error) {
-	log.Printf("Looking up all keys in keyring")
-	keys, err := s.Keyring.Keys()
-	if err != nil {
-		return nil, err
-	}
-
-	var sessions []KeyringSession
-
-	for _, k := range keys {
-		if IsSessionKey(k) {
-			ks, err := parseSessionKey(k, s.Config)
-			if err != nil || ks.IsExpired() {
-				log.Printf("Session %s is obsolete, attempting deleting", k)
-				if err := s.Keyring.Remove(k); err != nil {
-					log.Printf("Error deleting session: %v", err)
-				}
-				continue
-			}
-
-			sessions = append(sessions, ks)
-		}
-	}
-
-	return sessions, nil
+	return s.Backend.List()
 }
 
 // Retrieve searches sessions for specific profile, expects the profile to be provided, not the source
-func (s *KeyringSessions) Retrieve(profile string, mfaSerial string) (creds sts.Credentials, err error) {
-	log.Printf("Looking for sessions for %s", profile)
-	sessions, err := s.Sessions()
-	if err != nil {
-		return creds, err
-	}
-
-	for _, session := range sessions {
-		if session.Profile.Name == profile && session.MfaSerial == mfaSerial {
-			item, err := s.Keyring.Get(session.Key)
-			if err != nil {
-				return creds, err
-			}
-
-			if err = json.Unmarshal(item.Data, &creds); err != nil {
-				return creds, err
-			}
-
-			// double check the actual expiry time
-			if creds.Expiration.Before(time.Now()) {
-				log.Printf("Session %q is expired, deleting", session.Key)
-				if err = s.Keyring.Remove(session.Profile.Name); err != nil {
-					return creds, err
-				}
-			}
-
-			// success!
-			return creds, nil
-		}
-	}
-
-	return creds, keyring.ErrKeyNotFound
+func (s *KeyringSessions) Retrieve(profile string, mfaSerial string) (sts.Credentials, error) {
+	return s.Backend.Get(profile, mfaSerial)
 }
 
 // Store stores a sessions for a specific profile, expects the profile to be provided, not the source
 func (s *KeyringSessions) Store(profile string, mfaSerial string, session sts.Credentials) error {
-	bytes, err := json.Marshal(session)
-	if err != nil {
-		return err
-	}
-
-	key := formatSessionKey(profile, mfaSerial, session.Expiration)
-	log.Printf("Writing session for %s to keyring: %q", profile, key)
-
-	return s.Keyring.Set(keyring.Item{
-		Key:         key,
-		Label:       "aws-vault session for " + profile,
-		Description: "aws-vault session for " + profile,
-		Data:        bytes,
-
-		// specific Keychain settings
-		KeychainNotTrustApplication: false,
-	})
+	return s.Backend.Put(profile, mfaSerial, session)
 }
 
 // Delete deletes any sessions for a specific profile, expects the profile to be provided, not the source
-func (s *KeyringSessions) Delete(profile string) (n int, err error) {
-	log.Printf("Looking for sessions for %s", profile)
-	sessions, err := s.Sessions()
-	if err != nil {
-		return n, err
-	}
-
-	for _, session := range sessions {
-		if session.Profile.Name == profile {
-			log.Printf("Session %q matches profile %q", session.Key, profile)
-			if err = s.Keyring.Remove(session.Key); err != nil {
-				return n, err
-			}
-			n++
-		}
-	}
-
-	return
+func (s *KeyringSessions) Delete(profile string) (int, error) {
+	return s.Backend.Delete(profile)
 }
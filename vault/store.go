@@ -0,0 +1,78 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/99designs/keyring"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// SessionStore persists cached STS sessions. Implementations back
+// KeyringSessions and are responsible for their own expiry bookkeeping:
+// List should prune anything it finds expired, and Get treats an expired
+// session as missing. Whether Delete's count includes sessions that had
+// already expired differs between implementations.
+type SessionStore interface {
+	// Get returns the cached credentials for profile/mfaSerial, or
+	// keyring.ErrKeyNotFound if there's no current session.
+	Get(profile string, mfaSerial string) (sts.Credentials, error)
+
+	// Put caches session for profile/mfaSerial, replacing any existing
+	// session for the same pair.
+	Put(profile string, mfaSerial string, session sts.Credentials) error
+
+	// List returns every non-expired cached session.
+	List() ([]KeyringSession, error)
+
+	// Delete removes every cached session for profile and reports how
+	// many were removed.
+	Delete(profile string) (int, error)
+}
+
+// Session store kinds accepted by NewSessionStore and the
+// --session-store flag / SessionStoreEnvVar.
+const (
+	SessionStoreKeyring = "keyring"
+	SessionStoreFile    = "file"
+	SessionStoreMemory  = "memory"
+	SessionStoreKeyCtl  = "keyctl"
+)
+
+// SessionStoreEnvVar overrides which SessionStore implementation is used
+// when no --session-store flag is given.
+const SessionStoreEnvVar = "AWS_VAULT_SESSION_STORE"
+
+// NewSessionStore builds the SessionStore named by kind. An empty kind
+// selects SessionStoreKeyring, preserving the historical default. k and
+// passphrase are only consulted by the stores that need them; keyCtlScope
+// and keyCtlPerm are only consulted by SessionStoreKeyCtl, and are ignored
+// (and return an error, since the kernel keyring is Linux-only) on other
+// platforms.
+func NewSessionStore(kind string, cfg *Config, k keyring.Keyring, passphrase []byte, keyCtlScope KeyCtlScope, keyCtlPerm KeyCtlPerm) (SessionStore, error) {
+	switch kind {
+	case "", SessionStoreKeyring:
+		if k == nil {
+			return nil, fmt.Errorf("session store %q requires a keyring", SessionStoreKeyring)
+		}
+		return &keyringSessionStore{Keyring: k, Config: cfg}, nil
+	case SessionStoreFile:
+		return NewFileSessionStore(cfg, passphrase)
+	case SessionStoreMemory:
+		return NewMemorySessionStore(cfg), nil
+	case SessionStoreKeyCtl:
+		kr, err := NewKeyCtlKeyring(keyCtlScope, keyCtlPerm)
+		if err != nil {
+			return nil, err
+		}
+		return &keyringSessionStore{Keyring: kr, Config: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown session store %q", kind)
+	}
+}
+
+// SessionStoreKindFromEnv reads SessionStoreEnvVar, for callers wiring up
+// the --session-store flag's default.
+func SessionStoreKindFromEnv() string {
+	return os.Getenv(SessionStoreEnvVar)
+}